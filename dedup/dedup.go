@@ -0,0 +1,123 @@
+// Package dedup computes the fingerprints used to deduplicate and cluster
+// syndicated news across publishers: a canonical-URL hash, a GUID-based
+// entry ID, and a SimHash over the sanitized description for near-duplicate
+// clustering.
+package dedup
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"strings"
+)
+
+// trackingParams are query-string params that vary across otherwise
+// identical syndication links (UTM campaign tags, click trackers, ...).
+var trackingParams = map[string]bool{
+	"utm_source": true, "utm_medium": true, "utm_campaign": true,
+	"utm_term": true, "utm_content": true, "utm_id": true,
+	"fbclid": true, "gclid": true, "mc_cid": true, "mc_eid": true,
+	"ref": true, "ref_src": true, "spref": true, "CMP": true,
+}
+
+// CanonicalURL strips tracking query parameters and lowercases the
+// scheme/host, so the same article republished with different campaign
+// tags hashes the same.
+func CanonicalURL(rawURL string) string {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || u.Host == "" {
+		return rawURL
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = strings.ToLower(u.Host)
+	u.Fragment = ""
+	u.Path = strings.TrimSuffix(u.Path, "/")
+
+	if u.RawQuery != "" {
+		q := u.Query()
+		for key := range q {
+			if trackingParams[key] || strings.HasPrefix(strings.ToLower(key), "utm_") {
+				q.Del(key)
+			}
+		}
+		u.RawQuery = q.Encode()
+	}
+
+	return u.String()
+}
+
+// URLHash returns a stable fingerprint of a link's canonical form.
+func URLHash(rawURL string) string {
+	return sha256Hex(CanonicalURL(rawURL))
+}
+
+// EntryID returns a stable fingerprint derived from a feed's own guid/id
+// when the publisher marked it as a non-permalink stable identifier, plus
+// whether one was usable at all. The fingerprint is scoped by source (the
+// feed URL the item came from) because RSS only guarantees guid uniqueness
+// within a single channel — two unrelated feeds both emitting sequential
+// CMS post IDs would otherwise hash to the same entry_id.
+func EntryID(source, guid string, isPermaLink bool) (string, bool) {
+	guid = strings.TrimSpace(guid)
+	if guid == "" || isPermaLink {
+		return "", false
+	}
+	return sha256Hex(source + "|" + guid), true
+}
+
+func sha256Hex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+// SimHash computes a 64-bit SimHash over text's lowercased, whitespace-
+// separated tokens, so near-duplicate descriptions (the same AP wire story
+// reworded slightly by ten outlets) land close together in Hamming
+// distance.
+func SimHash(text string) uint64 {
+	var weights [64]int
+
+	for _, token := range strings.Fields(strings.ToLower(text)) {
+		h := fnv1a64(token)
+		for bit := 0; bit < 64; bit++ {
+			if h&(1<<uint(bit)) != 0 {
+				weights[bit]++
+			} else {
+				weights[bit]--
+			}
+		}
+	}
+
+	var sim uint64
+	for bit := 0; bit < 64; bit++ {
+		if weights[bit] > 0 {
+			sim |= 1 << uint(bit)
+		}
+	}
+	return sim
+}
+
+func fnv1a64(s string) uint64 {
+	const offset64 = 14695981039346656037
+	const prime64 = 1099511628211
+
+	hash := uint64(offset64)
+	for i := 0; i < len(s); i++ {
+		hash ^= uint64(s[i])
+		hash *= prime64
+	}
+	return hash
+}
+
+// HammingDistance returns the number of differing bits between two
+// SimHash values.
+func HammingDistance(a, b uint64) int {
+	x := a ^ b
+	count := 0
+	for x != 0 {
+		count++
+		x &= x - 1
+	}
+	return count
+}
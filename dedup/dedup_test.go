@@ -0,0 +1,55 @@
+package dedup
+
+import "testing"
+
+func TestCanonicalURLStripsTrackingParamsAndFragment(t *testing.T) {
+	got := CanonicalURL("HTTPS://Example.com/story/?utm_source=newsletter&id=42#section")
+	want := "https://example.com/story?id=42"
+	if got != want {
+		t.Fatalf("CanonicalURL() = %q, want %q", got, want)
+	}
+}
+
+func TestURLHashIsStableAcrossTrackingParams(t *testing.T) {
+	a := URLHash("https://example.com/story?utm_campaign=spring")
+	b := URLHash("https://example.com/story?utm_campaign=summer")
+	if a != b {
+		t.Fatalf("expected URLHash to ignore tracking params, got %q vs %q", a, b)
+	}
+}
+
+func TestEntryIDScopesByFeedSourceToAvoidCrossFeedCollisions(t *testing.T) {
+	idA, okA := EntryID("https://a.example.com/feed", "123", false)
+	idB, okB := EntryID("https://b.example.com/feed", "123", false)
+	if !okA || !okB {
+		t.Fatalf("expected both non-permalink guids to be usable, got okA=%v okB=%v", okA, okB)
+	}
+	if idA == idB {
+		t.Fatalf("expected the same guid from two different feeds to produce different entry IDs, got %q for both", idA)
+	}
+}
+
+func TestEntryIDRejectsPermaLinkGUIDs(t *testing.T) {
+	if _, ok := EntryID("https://example.com/feed", "https://example.com/story", true); ok {
+		t.Fatalf("expected a permalink guid to be rejected, since the URL hash already covers it")
+	}
+}
+
+func TestEntryIDRejectsEmptyGUID(t *testing.T) {
+	if _, ok := EntryID("https://example.com/feed", "", false); ok {
+		t.Fatalf("expected an empty guid to be rejected")
+	}
+}
+
+func TestSimHashPutsNearDuplicateTextCloserThanUnrelatedText(t *testing.T) {
+	a := SimHash("Authorities said the fire started in a warehouse downtown")
+	b := SimHash("Officials said the fire started in a downtown warehouse")
+	c := SimHash("The central bank raised interest rates by a quarter point")
+
+	if d := HammingDistance(a, b); d > 8 {
+		t.Fatalf("expected near-duplicate wording to stay within 8 bits, got Hamming distance %d", d)
+	}
+	if d := HammingDistance(a, c); d <= 8 {
+		t.Fatalf("expected an unrelated story to be far apart, got Hamming distance %d", d)
+	}
+}
@@ -0,0 +1,103 @@
+// Package discovery finds candidate syndication feeds for a publisher's
+// homepage, either by reading the <link rel="alternate"> tags it advertises
+// or by probing the paths publishers conventionally serve feeds from.
+package discovery
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Candidate is a feed URL worth trying, along with the MIME type it was
+// advertised under (empty when it came from a probed path instead).
+type Candidate struct {
+	URL  string
+	Type string
+}
+
+// CommonPaths are the well-known feed paths to probe when a homepage
+// doesn't advertise one via <link rel="alternate">.
+var CommonPaths = []string{
+	"/feed",
+	"/rss",
+	"/rss.xml",
+	"/atom.xml",
+	"/feed.json",
+	"/index.xml",
+	"/?feed=rss2",
+}
+
+// feedMIMETypes are the <link type="..."> values that mark a feed rather
+// than, say, a stylesheet or a canonical link.
+var feedMIMETypes = map[string]bool{
+	"application/rss+xml":   true,
+	"application/atom+xml":  true,
+	"application/json":      true,
+	"application/feed+json": true,
+}
+
+// LinkTags parses pageHTML for <link rel="alternate" type="..."> tags that
+// advertise a syndication feed, resolving hrefs against pageURL.
+func LinkTags(pageHTML, pageURL string) []Candidate {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(pageHTML))
+	if err != nil {
+		return nil
+	}
+
+	var candidates []Candidate
+	doc.Find(`link[rel="alternate"]`).Each(func(_ int, sel *goquery.Selection) {
+		typ, _ := sel.Attr("type")
+		if !feedMIMETypes[strings.ToLower(typ)] {
+			return
+		}
+		href, ok := sel.Attr("href")
+		if !ok || href == "" {
+			return
+		}
+		candidates = append(candidates, Candidate{URL: resolve(href, pageURL), Type: typ})
+	})
+
+	return candidates
+}
+
+// ProbeURLs returns the CommonPaths resolved against pageURL, as candidates
+// to try when LinkTags found nothing (or to supplement what it did find).
+func ProbeURLs(pageURL string) []Candidate {
+	candidates := make([]Candidate, 0, len(CommonPaths))
+	for _, path := range CommonPaths {
+		candidates = append(candidates, Candidate{URL: joinPath(pageURL, path)})
+	}
+	return candidates
+}
+
+func resolve(href, base string) string {
+	b, err := url.Parse(base)
+	if err != nil {
+		return href
+	}
+	u, err := url.Parse(href)
+	if err != nil {
+		return href
+	}
+	return b.ResolveReference(u).String()
+}
+
+// joinPath appends path to base's own path (or, for a WordPress-style query
+// probe like "?feed=rss2", sets it as the query string instead).
+func joinPath(base, path string) string {
+	u, err := url.Parse(base)
+	if err != nil {
+		return ""
+	}
+
+	if strings.HasPrefix(path, "?") {
+		u.RawQuery = strings.TrimPrefix(path, "?")
+		return u.String()
+	}
+
+	u.Path = strings.TrimRight(u.Path, "/") + path
+	u.RawQuery = ""
+	return u.String()
+}
@@ -0,0 +1,40 @@
+package discovery
+
+import "testing"
+
+func TestLinkTagsResolvesHrefAndIgnoresNonFeedTypes(t *testing.T) {
+	html := `
+		<link rel="alternate" type="application/rss+xml" href="/rss.xml">
+		<link rel="alternate" type="text/css" href="/style.css">
+		<link rel="canonical" href="https://example.com/">
+	`
+	candidates := LinkTags(html, "https://example.com/news/")
+
+	if len(candidates) != 1 {
+		t.Fatalf("expected exactly one feed candidate, got %+v", candidates)
+	}
+	if candidates[0].URL != "https://example.com/rss.xml" {
+		t.Fatalf("expected href to resolve against the page URL, got %q", candidates[0].URL)
+	}
+}
+
+func TestProbeURLsCoversEveryCommonPath(t *testing.T) {
+	candidates := ProbeURLs("https://example.com/")
+	if len(candidates) != len(CommonPaths) {
+		t.Fatalf("expected one candidate per common path, got %d", len(candidates))
+	}
+}
+
+func TestJoinPathAppendsToExistingPath(t *testing.T) {
+	got := joinPath("https://example.com/section/", "/feed")
+	if got != "https://example.com/section/feed" {
+		t.Fatalf("joinPath() = %q", got)
+	}
+}
+
+func TestJoinPathSetsQueryForQueryStyleProbes(t *testing.T) {
+	got := joinPath("https://example.com/", "?feed=rss2")
+	if got != "https://example.com/?feed=rss2" {
+		t.Fatalf("joinPath() = %q", got)
+	}
+}
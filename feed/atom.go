@@ -0,0 +1,123 @@
+package feed
+
+import (
+	"time"
+
+	"github.com/seheraksam/worldnewsapi/media"
+)
+
+type atomDoc struct {
+	Title   string      `xml:"title"`
+	Link    []atomLink  `xml:"link"`
+	Icon    string      `xml:"icon"`
+	Logo    string      `xml:"logo"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr"`
+}
+
+type atomEntry struct {
+	ID           string              `xml:"id"`
+	Title        string              `xml:"title"`
+	Summary      string              `xml:"summary"`
+	Content      atomContent         `xml:"content"`
+	Link         []atomLink          `xml:"link"`
+	Author       atomAuthor          `xml:"author"`
+	Published    string              `xml:"published"`
+	Updated      string              `xml:"updated"`
+	MediaGroup   *media.MediaGroup   `xml:"http://search.yahoo.com/mrss/ group"`
+	MediaContent *media.MediaContent `xml:"http://search.yahoo.com/mrss/ content"`
+}
+
+type atomContent struct {
+	Type string `xml:"type,attr"`
+	Text string `xml:",chardata"`
+	// XHTML content nests a <div>; innerxml keeps the markup intact
+	// instead of collapsing it to chardata.
+	InnerXML string `xml:",innerxml"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+// atomLinkByRel returns the href of the first link with the given rel,
+// defaulting an omitted rel attribute to "alternate" per the Atom spec.
+func atomLinkByRel(links []atomLink, rel string) string {
+	for _, l := range links {
+		effectiveRel := l.Rel
+		if effectiveRel == "" {
+			effectiveRel = "alternate"
+		}
+		if effectiveRel == rel {
+			return l.Href
+		}
+	}
+	return ""
+}
+
+func parseAtom(body []byte) (*Feed, error) {
+	var doc atomDoc
+	if err := newDecoder(body).Decode(&doc); err != nil {
+		return nil, err
+	}
+
+	image := doc.Icon
+	if image == "" {
+		image = doc.Logo
+	}
+
+	f := &Feed{
+		Format: FormatAtom,
+		Title:  doc.Title,
+		Link:   atomLinkByRel(doc.Link, "alternate"),
+		Image:  image,
+	}
+
+	for _, e := range doc.Entries {
+		contentHTML := e.Content.Text
+		if e.Content.Type == "xhtml" {
+			contentHTML = e.Content.InnerXML
+		}
+
+		link := atomLinkByRel(e.Link, "alternate")
+		if link == "" {
+			link = atomLinkByRel(e.Link, "enclosure")
+		}
+
+		item := Item{
+			// atom:id is guaranteed by RFC 4287 §4.2.6 to be a stable,
+			// non-dereferenceable identifier, never a permalink.
+			GUID:        e.ID,
+			IsPermaLink: false,
+			Link:        link,
+			Title:       e.Title,
+			Summary:     e.Summary,
+			ContentHTML: contentHTML,
+			Author:      e.Author.Name,
+			Media: media.Source{
+				Group: e.MediaGroup,
+				HTML:  contentHTML,
+			},
+		}
+		if e.MediaContent != nil {
+			item.Media.Contents = []media.MediaContent{*e.MediaContent}
+		}
+
+		published := e.Published
+		if published == "" {
+			published = e.Updated
+		}
+		if t, err := time.Parse(time.RFC3339, published); err == nil {
+			item.PubDate, item.HasPubDate = t, true
+		}
+
+		f.Items = append(f.Items, item)
+	}
+
+	return f, nil
+}
@@ -0,0 +1,121 @@
+// Package feed normalizes RSS 2.0, RDF/RSS 1.0, Atom 1.0 and JSON Feed 1.1
+// documents into a single Feed/Item model, so the rest of the service
+// doesn't need to care which syndication format a publisher chose to ship.
+package feed
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"time"
+
+	"golang.org/x/net/html/charset"
+
+	"github.com/seheraksam/worldnewsapi/media"
+)
+
+// Format identifies which syndication format a Feed was decoded from.
+type Format string
+
+const (
+	FormatRSS  Format = "rss"
+	FormatAtom Format = "atom"
+	FormatRDF  Format = "rdf"
+	FormatJSON Format = "json"
+)
+
+// Feed is the normalized representation of a channel/feed, regardless of
+// source format.
+type Feed struct {
+	Format      Format
+	Title       string
+	Link        string
+	Description string
+	Language    string
+	// Image is the channel's icon/logo (RSS <image><url>, Atom <icon>/
+	// <logo>, JSON Feed "icon"/"favicon"); empty when the publisher didn't
+	// advertise one.
+	Image string
+	// TTLMinutes, SkipHours and SkipDays are the RSS 2.0 update hints
+	// (<ttl>, <skipHours>, <skipDays>); zero/nil on formats that don't
+	// define them.
+	TTLMinutes int
+	SkipHours  []int
+	SkipDays   []string
+	Items      []Item
+}
+
+// Item is a single normalized entry.
+type Item struct {
+	GUID        string
+	IsPermaLink bool
+	Link        string
+	// AtomLink is an RSS item's <atom:link href="...">, the permalink some
+	// publishers (notably CNN) ship instead of a plain <link> element.
+	AtomLink    string
+	Title       string
+	Summary     string
+	ContentHTML string
+	Author      string
+	PubDate     time.Time
+	HasPubDate  bool
+	Media       media.Source
+	// Tags are the item's own categories/keywords (currently only
+	// populated from JSON Feed's "tags"); empty on formats that don't
+	// carry per-item tags.
+	Tags []string
+}
+
+// Parse sniffs body and dispatches to the matching format-specific decoder:
+// JSON Feed when it starts with '{', otherwise whichever XML root element
+// (RSS, Atom or RDF) comes first. Non-UTF8 XML documents (Windows-1254,
+// ISO-8859-1, ...) are transparently decoded using their declared charset.
+func Parse(body []byte) (*Feed, error) {
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) > 0 && trimmed[0] == '{' {
+		return parseJSONFeed(body)
+	}
+
+	root, err := rootElement(body)
+	if err != nil {
+		return nil, fmt.Errorf("feed: sniffing root element: %w", err)
+	}
+
+	switch root {
+	case "rss":
+		return parseRSS(body)
+	case "feed":
+		return parseAtom(body)
+	case "RDF":
+		return parseRDF(body)
+	default:
+		return nil, fmt.Errorf("feed: unrecognized root element %q", root)
+	}
+}
+
+// rootElement returns the local name of the document's root XML element,
+// so callers can pick the right format-specific parser before paying for a
+// full unmarshal.
+func rootElement(body []byte) (string, error) {
+	decoder := newDecoder(body)
+
+	for {
+		tok, err := decoder.Token()
+		if err != nil {
+			return "", err
+		}
+		if start, ok := tok.(xml.StartElement); ok {
+			return start.Name.Local, nil
+		}
+	}
+}
+
+// newDecoder returns an xml.Decoder that decodes non-UTF8 documents using
+// the charset declared in the XML prolog; the HTTP Content-Type header is
+// not consulted.
+func newDecoder(body []byte) *xml.Decoder {
+	decoder := xml.NewDecoder(bytes.NewReader(body))
+	decoder.CharsetReader = charset.NewReaderLabel
+	decoder.Strict = false
+	return decoder
+}
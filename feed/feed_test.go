@@ -0,0 +1,141 @@
+package feed
+
+import "testing"
+
+func TestParseDispatchesOnRootElement(t *testing.T) {
+	rss := []byte(`<rss><channel><title>RSS</title></channel></rss>`)
+	if f, err := Parse(rss); err != nil || f.Format != FormatRSS {
+		t.Fatalf("expected rss dispatch, got %+v, err %v", f, err)
+	}
+
+	atom := []byte(`<feed><title>Atom</title></feed>`)
+	if f, err := Parse(atom); err != nil || f.Format != FormatAtom {
+		t.Fatalf("expected atom dispatch, got %+v, err %v", f, err)
+	}
+
+	jsonFeed := []byte(`{"version":"https://jsonfeed.org/version/1.1","title":"JSON","items":[]}`)
+	if f, err := Parse(jsonFeed); err != nil || f.Format != FormatJSON {
+		t.Fatalf("expected json feed dispatch, got %+v, err %v", f, err)
+	}
+}
+
+func TestParseRSSGUIDDefaultsToPermaLinkWhenAttributeOmitted(t *testing.T) {
+	body := []byte(`<rss><channel><item><guid>123</guid></item></channel></rss>`)
+	f, err := Parse(body)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !f.Items[0].IsPermaLink {
+		t.Fatalf("expected an omitted isPermaLink attribute to default to true per the RSS 2.0 spec")
+	}
+}
+
+func TestParseRSSGUIDRespectsExplicitIsPermaLinkFalse(t *testing.T) {
+	body := []byte(`<rss><channel><item><guid isPermaLink="false">abc-123</guid></item></channel></rss>`)
+	f, err := Parse(body)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if f.Items[0].IsPermaLink {
+		t.Fatalf("expected isPermaLink=\"false\" to be honored")
+	}
+}
+
+func TestParseRSSCapturesAtomLinkWhenLinkMissing(t *testing.T) {
+	body := []byte(`<rss xmlns:atom="http://www.w3.org/2005/Atom"><channel>
+		<item><atom:link href="https://example.com/story"/></item>
+	</channel></rss>`)
+	f, err := Parse(body)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if f.Items[0].AtomLink != "https://example.com/story" {
+		t.Fatalf("expected atom:link to be captured, got %q", f.Items[0].AtomLink)
+	}
+}
+
+func TestParseRSSPrefersFeedburnerOrigLinkOverTrackingLink(t *testing.T) {
+	body := []byte(`<rss xmlns:feedburner="http://rssnamespace.org/feedburner/ext/1.0"><channel>
+		<item>
+			<link>https://feedproxy.google.com/~r/example/~3/abc</link>
+			<feedburner:origLink>https://example.com/real-story</feedburner:origLink>
+		</item>
+	</channel></rss>`)
+	f, err := Parse(body)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if f.Items[0].Link != "https://example.com/real-story" {
+		t.Fatalf("expected feedburner origLink to win over the tracking link, got %q", f.Items[0].Link)
+	}
+}
+
+func TestParseRSSScrapeSourcePrefersContentEncodedOverDescription(t *testing.T) {
+	body := []byte(`<rss xmlns:content="http://purl.org/rss/1.0/modules/content/"><channel>
+		<item>
+			<description>short summary, no image</description>
+			<content:encoded><![CDATA[<p>full body</p><img src="https://example.com/a.jpg">]]></content:encoded>
+		</item>
+	</channel></rss>`)
+	f, err := Parse(body)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if f.Items[0].Media.HTML != `<p>full body</p><img src="https://example.com/a.jpg">` {
+		t.Fatalf("expected content:encoded to be preferred as the scrape source, got %q", f.Items[0].Media.HTML)
+	}
+}
+
+func TestParseAtomIDIsNeverAPermaLink(t *testing.T) {
+	body := []byte(`<feed><entry><id>urn:uuid:1</id></entry></feed>`)
+	f, err := Parse(body)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if f.Items[0].IsPermaLink {
+		t.Fatalf("expected atom:id to never be treated as a permalink")
+	}
+}
+
+func TestParseAtomFallsBackToUpdatedWhenPublishedMissing(t *testing.T) {
+	body := []byte(`<feed><entry><id>1</id><updated>2024-03-01T12:00:00Z</updated></entry></feed>`)
+	f, err := Parse(body)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !f.Items[0].HasPubDate {
+		t.Fatalf("expected updated to be used as a pubDate fallback")
+	}
+}
+
+func TestParseJSONFeedCarriesItemTags(t *testing.T) {
+	body := []byte(`{
+		"version": "https://jsonfeed.org/version/1.1",
+		"title": "JSON",
+		"items": [{"id": "1", "title": "story", "tags": ["politics", "economy"]}]
+	}`)
+	f, err := Parse(body)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(f.Items[0].Tags) != 2 || f.Items[0].Tags[0] != "politics" || f.Items[0].Tags[1] != "economy" {
+		t.Fatalf("expected item tags to be carried through, got %v", f.Items[0].Tags)
+	}
+}
+
+func TestParseRDFUsesLinkAsGUID(t *testing.T) {
+	body := []byte(`<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#">
+		<channel><title>RDF</title></channel>
+		<item><link>https://example.com/story</link></item>
+	</rdf:RDF>`)
+	f, err := Parse(body)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if f.Format != FormatRDF {
+		t.Fatalf("expected RDF dispatch, got %q", f.Format)
+	}
+	if f.Items[0].GUID != "https://example.com/story" || !f.Items[0].IsPermaLink {
+		t.Fatalf("expected the item link to double as a permalink guid, got %+v", f.Items[0])
+	}
+}
@@ -0,0 +1,117 @@
+package feed
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/seheraksam/worldnewsapi/media"
+)
+
+// JSONFeedVersion identifies the spec version this package reads and
+// writes; https://www.jsonfeed.org/version/1.1/ is the current one.
+const JSONFeedVersion = "https://jsonfeed.org/version/1.1"
+
+// JSONFeedDocument is the top-level JSON Feed 1.1 document. It doubles as
+// both the shape parseJSONFeed decodes from a publisher and the shape
+// /feeds/:category.json encodes back out, since the two are symmetric.
+type JSONFeedDocument struct {
+	Version     string         `json:"version"`
+	Title       string         `json:"title"`
+	HomePageURL string         `json:"home_page_url,omitempty"`
+	FeedURL     string         `json:"feed_url,omitempty"`
+	Description string         `json:"description,omitempty"`
+	Icon        string         `json:"icon,omitempty"`
+	Favicon     string         `json:"favicon,omitempty"`
+	Language    string         `json:"language,omitempty"`
+	NextURL     string         `json:"next_url,omitempty"`
+	Items       []JSONFeedItem `json:"items"`
+}
+
+// JSONFeedAuthor is a JSON Feed 1.1 author object; only the name is used
+// today, the spec's url/avatar fields aren't populated by this service.
+type JSONFeedAuthor struct {
+	Name string `json:"name,omitempty"`
+}
+
+// JSONFeedAttachment is a JSON Feed 1.1 attachment object.
+type JSONFeedAttachment struct {
+	URL      string `json:"url"`
+	MimeType string `json:"mime_type,omitempty"`
+}
+
+// JSONFeedItem is a single JSON Feed 1.1 item.
+type JSONFeedItem struct {
+	ID            string               `json:"id"`
+	URL           string               `json:"url,omitempty"`
+	Title         string               `json:"title,omitempty"`
+	ContentHTML   string               `json:"content_html,omitempty"`
+	ContentText   string               `json:"content_text,omitempty"`
+	Summary       string               `json:"summary,omitempty"`
+	DatePublished string               `json:"date_published,omitempty"`
+	Authors       []JSONFeedAuthor     `json:"authors,omitempty"`
+	Tags          []string             `json:"tags,omitempty"`
+	Attachments   []JSONFeedAttachment `json:"attachments,omitempty"`
+}
+
+// parseJSONFeed decodes a JSON Feed 1.1 document into the normalized Feed
+// model. Per spec, an item's id is an opaque stable identifier rather than
+// a permalink, so it's carried through as a non-permalink GUID.
+func parseJSONFeed(body []byte) (*Feed, error) {
+	var doc JSONFeedDocument
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return nil, err
+	}
+
+	image := doc.Icon
+	if image == "" {
+		image = doc.Favicon
+	}
+
+	f := &Feed{
+		Format:      FormatJSON,
+		Title:       doc.Title,
+		Link:        doc.HomePageURL,
+		Description: doc.Description,
+		Language:    doc.Language,
+		Image:       image,
+	}
+
+	for _, it := range doc.Items {
+		summary := it.Summary
+		if summary == "" {
+			summary = it.ContentText
+		}
+
+		var author string
+		if len(it.Authors) > 0 {
+			author = it.Authors[0].Name
+		}
+
+		item := Item{
+			GUID:        it.ID,
+			IsPermaLink: false,
+			Link:        it.URL,
+			Title:       it.Title,
+			Summary:     summary,
+			ContentHTML: it.ContentHTML,
+			Author:      author,
+			Media:       media.Source{HTML: it.ContentHTML},
+			Tags:        it.Tags,
+		}
+
+		for _, a := range it.Attachments {
+			item.Media.Contents = append(item.Media.Contents, media.MediaContent{
+				URL:  a.URL,
+				Type: a.MimeType,
+			})
+		}
+
+		if t, err := time.Parse(time.RFC3339, it.DatePublished); err == nil {
+			item.PubDate, item.HasPubDate = t, true
+		}
+
+		f.Items = append(f.Items, item)
+	}
+
+	return f, nil
+}
@@ -0,0 +1,74 @@
+package feed
+
+import (
+	"time"
+
+	"github.com/seheraksam/worldnewsapi/media"
+)
+
+// RDF/RSS 1.0 lists <item> as siblings of <channel> under the <rdf:RDF>
+// root instead of nesting them inside the channel the way RSS 2.0 does.
+type rdfDoc struct {
+	Channel rdfChannel `xml:"channel"`
+	Items   []rdfItem  `xml:"item"`
+}
+
+type rdfChannel struct {
+	Title       string `xml:"title"`
+	Link        string `xml:"link"`
+	Description string `xml:"description"`
+	Language    string `xml:"http://purl.org/dc/elements/1.1/ language"`
+}
+
+type rdfItem struct {
+	Link           string              `xml:"link"`
+	Title          string              `xml:"title"`
+	Description    string              `xml:"description"`
+	ContentEncoded string              `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+	Date           string              `xml:"http://purl.org/dc/elements/1.1/ date"`
+	Creator        string              `xml:"http://purl.org/dc/elements/1.1/ creator"`
+	MediaGroup     *media.MediaGroup   `xml:"http://search.yahoo.com/mrss/ group"`
+	MediaContent   *media.MediaContent `xml:"http://search.yahoo.com/mrss/ content"`
+}
+
+func parseRDF(body []byte) (*Feed, error) {
+	var doc rdfDoc
+	if err := newDecoder(body).Decode(&doc); err != nil {
+		return nil, err
+	}
+
+	f := &Feed{
+		Format:      FormatRDF,
+		Title:       doc.Channel.Title,
+		Link:        doc.Channel.Link,
+		Description: doc.Channel.Description,
+		Language:    doc.Channel.Language,
+	}
+
+	for _, it := range doc.Items {
+		item := Item{
+			// RDF items don't carry a dedicated guid element; the link
+			// is the de-facto stable identifier.
+			GUID:        it.Link,
+			IsPermaLink: true,
+			Link:        it.Link,
+			Title:       it.Title,
+			Summary:     it.Description,
+			ContentHTML: it.ContentEncoded,
+			Author:      it.Creator,
+			Media: media.Source{
+				Group: it.MediaGroup,
+				HTML:  it.Description,
+			},
+		}
+		if it.MediaContent != nil {
+			item.Media.Contents = []media.MediaContent{*it.MediaContent}
+		}
+		if t, err := time.Parse(time.RFC3339, it.Date); err == nil {
+			item.PubDate, item.HasPubDate = t, true
+		}
+		f.Items = append(f.Items, item)
+	}
+
+	return f, nil
+}
@@ -0,0 +1,189 @@
+package feed
+
+import (
+	"encoding/xml"
+	"time"
+
+	"github.com/seheraksam/worldnewsapi/media"
+)
+
+// atomNamespace is the XML namespace of Atom elements some RSS publishers
+// mix into their feeds, e.g. CNN's <atom:link href="...">.
+const atomNamespace = "http://www.w3.org/2005/Atom"
+
+type rssDoc struct {
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title       string    `xml:"title"`
+	Link        string    `xml:"link"`
+	Description string    `xml:"description"`
+	Language    string    `xml:"language"`
+	Image       rssImage  `xml:"image"`
+	TTL         int       `xml:"ttl"`
+	SkipHours   []int     `xml:"skipHours>hour"`
+	SkipDays    []string  `xml:"skipDays>day"`
+	Items       []rssItem `xml:"item"`
+}
+
+// rssImage is the channel's <image> element, used as the feed's icon.
+type rssImage struct {
+	URL string `xml:"url"`
+}
+
+type rssItem struct {
+	GUID *rssGUID `xml:"guid"`
+	// Links captures every <link> element on the item: RSS's own plain
+	// <link>url</link> and any namespaced <atom:link href="..."> some
+	// publishers (CNN among them) ship instead, both of which share the
+	// local name "link" and so can't be bound to two separate fields on one
+	// struct — encoding/xml resolves the ambiguity by failing to populate
+	// either. plainLink/atomLink below tell the two apart by namespace.
+	Links []rssLink `xml:"link"`
+	// FeedburnerOrigLink is <feedburner:origLink>, the real article URL
+	// Feedburner-proxied feeds carry alongside a <link> that actually
+	// points at a feedburner.com tracking redirect.
+	FeedburnerOrigLink string                `xml:"http://rssnamespace.org/feedburner/ext/1.0 origLink"`
+	Title              string                `xml:"title"`
+	Description        string                `xml:"description"`
+	ContentEncoded     string                `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+	PubDate            string                `xml:"pubDate"`
+	Creator            string                `xml:"http://purl.org/dc/elements/1.1/ creator"`
+	MediaGroup         *media.MediaGroup     `xml:"http://search.yahoo.com/mrss/ group"`
+	MediaContent       *media.MediaContent   `xml:"http://search.yahoo.com/mrss/ content"`
+	MediaThumbnail     *media.MediaThumbnail `xml:"http://search.yahoo.com/mrss/ thumbnail"`
+	Enclosure          *media.Enclosure      `xml:"enclosure"`
+}
+
+// rssLink is one <link> element of any namespace; XMLName.Space tells a
+// plain RSS <link> (Space == "") apart from a namespaced one like atom:link.
+type rssLink struct {
+	XMLName xml.Name
+	Href    string `xml:"href,attr"`
+	Value   string `xml:",chardata"`
+}
+
+// plainLink returns the item's ordinary, unnamespaced <link> text.
+func (it rssItem) plainLink() string {
+	for _, l := range it.Links {
+		if l.XMLName.Space == "" {
+			return l.Value
+		}
+	}
+	return ""
+}
+
+// atomLink returns the href of the item's <atom:link>, the permalink some
+// publishers (notably CNN) ship in place of a plain <link>.
+func (it rssItem) atomLink() string {
+	for _, l := range it.Links {
+		if l.XMLName.Space == atomNamespace {
+			return l.Href
+		}
+	}
+	return ""
+}
+
+type rssGUID struct {
+	Value string `xml:",chardata"`
+	// IsPermaAttr is read as a string rather than a bool because the RSS
+	// 2.0 spec's default for an *omitted* isPermaLink attribute is true,
+	// not Go's zero value for bool; isPermaLink() below applies that
+	// default explicitly.
+	IsPermaAttr string `xml:"isPermaLink,attr"`
+}
+
+// isPermaLink reports whether g's guid should be treated as a dereferenceable
+// permalink, defaulting to true (the RSS 2.0 spec default) when the
+// attribute is omitted entirely.
+func (g rssGUID) isPermaLink() bool {
+	return g.IsPermaAttr != "false"
+}
+
+// rssPubDateFormats covers the formats publishers actually send in
+// <pubDate>, which is nominally RFC 822 but rarely follows it exactly.
+var rssPubDateFormats = []string{
+	time.RFC1123Z,
+	time.RFC1123,
+	time.RFC3339,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006",
+}
+
+func parseRSSDate(value string) (time.Time, bool) {
+	for _, format := range rssPubDateFormats {
+		if t, err := time.Parse(format, value); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func parseRSS(body []byte) (*Feed, error) {
+	var doc rssDoc
+	if err := newDecoder(body).Decode(&doc); err != nil {
+		return nil, err
+	}
+
+	f := &Feed{
+		Format:      FormatRSS,
+		Title:       doc.Channel.Title,
+		Link:        doc.Channel.Link,
+		Description: doc.Channel.Description,
+		Language:    doc.Channel.Language,
+		Image:       doc.Channel.Image.URL,
+		TTLMinutes:  doc.Channel.TTL,
+		SkipHours:   doc.Channel.SkipHours,
+		SkipDays:    doc.Channel.SkipDays,
+	}
+
+	for _, it := range doc.Channel.Items {
+		// Prefer content:encoded as the scrape source for a fallback
+		// thumbnail: <description> is often a short summary with no image,
+		// while the full body in content:encoded usually has one.
+		scrapeHTML := it.ContentEncoded
+		if scrapeHTML == "" {
+			scrapeHTML = it.Description
+		}
+
+		// Feedburner-proxied feeds carry the real article URL in
+		// origLink; <link> itself just points at a feedburner.com
+		// tracking redirect, which would otherwise become the canonical
+		// URL every dedup/clustering decision hashes on.
+		link := it.plainLink()
+		if it.FeedburnerOrigLink != "" {
+			link = it.FeedburnerOrigLink
+		}
+
+		item := Item{
+			Link:        link,
+			AtomLink:    it.atomLink(),
+			Title:       it.Title,
+			Summary:     it.Description,
+			ContentHTML: it.ContentEncoded,
+			Author:      it.Creator,
+			Media: media.Source{
+				Group:     it.MediaGroup,
+				Enclosure: it.Enclosure,
+				HTML:      scrapeHTML,
+			},
+		}
+		if it.MediaContent != nil {
+			item.Media.Contents = []media.MediaContent{*it.MediaContent}
+		}
+		if it.MediaThumbnail != nil {
+			item.Media.Thumbnails = []media.MediaThumbnail{*it.MediaThumbnail}
+		}
+		if it.GUID != nil {
+			item.GUID = it.GUID.Value
+			item.IsPermaLink = it.GUID.isPermaLink()
+		}
+		if t, ok := parseRSSDate(it.PubDate); ok {
+			item.PubDate, item.HasPubDate = t, true
+		}
+		f.Items = append(f.Items, item)
+	}
+
+	return f, nil
+}
@@ -5,23 +5,30 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
-	"encoding/xml"
 	"fmt"
 	"io/ioutil"
 	"log"
+	"net"
 	"net/http"
+	"net/url"
 	"os"
 	"regexp"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
-	"github.com/PuerkitoBio/goquery"
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"golang.org/x/net/html"
+
+	"github.com/seheraksam/worldnewsapi/dedup"
+	"github.com/seheraksam/worldnewsapi/discovery"
+	"github.com/seheraksam/worldnewsapi/feed"
+	"github.com/seheraksam/worldnewsapi/media"
+	"github.com/seheraksam/worldnewsapi/sanitizer"
 )
 
 // MongoDB bağlantı bilgileri
@@ -29,95 +36,129 @@ const mongoURI = "mongodb://localhost:27017"
 const dbName = "dailypal"
 const collectionFeeds = "rss_feeds"
 const collectionNews = "news"
+const collectionFeedState = "feed_state"
 const workerCount = 5
 
-type RSS struct {
-	Channel Channel `xml:"channel"`
+// defaultFetchInterval is used when a feed publishes no <ttl>.
+const defaultFetchInterval = 30 * time.Minute
+
+// schedulerInterval is how often the background scheduler wakes up to check
+// which feeds are due; individual feeds are still gated by their own
+// NextFetchAt, so this just bounds the worst-case staleness.
+const schedulerInterval = time.Minute
+
+// FeedState is the per-feed conditional-GET and scheduling bookkeeping that
+// survives between fetch cycles, so we don't hit the same publisher again
+// before its TTL/SkipHours/SkipDays say we should.
+type FeedState struct {
+	URL           string    `bson:"url"`
+	ETag          string    `bson:"etag,omitempty"`
+	LastModified  string    `bson:"last_modified,omitempty"`
+	LastFetchedAt time.Time `bson:"last_fetched_at,omitempty"`
+	NextFetchAt   time.Time `bson:"next_fetch_at,omitempty"`
+	// Image is the source channel's icon/logo, carried along so
+	// /feeds/:category.json can surface it without re-fetching the feed.
+	Image string `bson:"image,omitempty"`
+	// TTLMinutes, SkipHours and SkipDays are the feed's own scheduling
+	// hints from its last successful parse, kept around so a 304 (which
+	// carries no body to re-read them from) still schedules the next
+	// fetch against the feed's real TTL instead of degrading to
+	// defaultFetchInterval.
+	TTLMinutes int      `bson:"ttl_minutes,omitempty"`
+	SkipHours  []int    `bson:"skip_hours,omitempty"`
+	SkipDays   []string `bson:"skip_days,omitempty"`
 }
 
-type Channel struct {
-	Title          string   `xml:"title"`
-	Link           string   `xml:"link"`
-	Description    string   `xml:"description"`
-	Language       string   `xml:"language"`
-	ManagingEditor string   `xml:"managingEditor"`
-	Category       string   `xml:"category"`
-	Image          RSSImage `xml:"image"`
-	Items          []Item   `xml:"item"`
+type fetchJob struct {
+	url      string
+	category string
+	state    FeedState
 }
 
-type RSSImage struct {
-	Title string `xml:"title"`
-	Link  string `xml:"link"`
-	URL   string `xml:"url"`
+type News struct {
+	Title         string             `bson:"title"`
+	Link          string             `bson:"link"`
+	Description   string             `bson:"description"`
+	Content       string             `bson:"content,omitempty"`
+	PubDate       time.Time          `bson:"pub_date"`
+	Category      []string           `bson:"category"`
+	Source        string             `bson:"source"`
+	Creator       string             `bson:"creator"`
+	Language      string             `bson:"language"`
+	LastBuildDate time.Time          `bson:"last_build_date"`
+	ImageUrl      string             `bson:"imageUrl"`
+	SubCategory   string             `bson:"sub_category"`
+	Hash          string             `bson:"generatedHash"`
+	Attachments   []media.Attachment `bson:"attachments,omitempty"`
+	// CanonicalURLHash and EntryID are the two halves of the $or upsert
+	// filter: a story the same feed re-publishes under a different URL
+	// still matches on EntryID, and a story whose guid changes (or that
+	// has no usable guid) but keeps its link still matches on
+	// CanonicalURLHash.
+	CanonicalURLHash string `bson:"canonical_url_hash,omitempty"`
+	EntryID          string `bson:"entry_id,omitempty"`
+	// SimHash and ClusterID group near-duplicate stories (the same wire
+	// story picked up by several outlets) without requiring an exact
+	// fingerprint match.
+	SimHash   int64  `bson:"simhash,omitempty"`
+	ClusterID string `bson:"cluster_id,omitempty"`
 }
 
-type MediaContent struct {
-	URL        string `xml:"url,attr"`
-	Type       string `xml:"type,attr"`
-	Width      int    `xml:"width,attr"`
-	Height     int    `xml:"height,attr"`
-	Expression string `xml:"expression,attr"`
-	URL2       string `xml:",chardata"`
-}
+// simHashClusterThreshold is the maximum Hamming distance between two
+// SimHash values for them to be considered the same story.
+const simHashClusterThreshold = 3
 
-type MediaThumbnail struct {
-	URL    string `xml:"url,attr"`
-	Width  int    `xml:"width,attr"`
-	Height int    `xml:"height,attr"`
-}
+// simHashClusterScanLimit bounds how many recent fingerprinted documents we
+// compare a new item's SimHash against when looking for a cluster to join.
+const simHashClusterScanLimit = 500
 
-type Item struct {
-	GUID              *GuidIsPermaLink `xml:"guid"`
-	Link              string           `xml:"link"`
-	Title             string           `xml:"title"`
-	Description       string           `xml:"description"`
-	PubDate           string           `xml:"pubDate"`
-	AtomLink          string           `xml:"http://www.w3.org/2005/Atom link,attr"`
-	Image             string           `xml:"image"`
-	MediaContent      *MediaContent    `xml:"media:content"`
-	MediaThumbnail    *MediaThumbnail  `xml:"media:thumbnail"`
-	Enclosure         *Enclosure       `xml:"enclosure"`
-	IpImage           string           `xml:"ipimage"`
-	Creator           string           `xml:"http://purl.org/dc/elements/1.1/ creator"`
-	MediaContentfor   *MediaContent    `xml:"http://search.yahoo.com/mrss/ content"`
-	MediaThumbnailfor *MediaThumbnail  `xml:"http://search.yahoo.com/mrss/ thumbnail"`
-	FigureImage       string           `xml:"figure>img"`
-	ContentEncoded    string           `xml:"content:encoded"`
-	AtomLinkforCNN    *AtomLinkforCnn  `xml:"atom:link"`
+var client *mongo.Client
+var mediaExtractor = media.NewExtractor()
+var htmlSanitizer = sanitizer.New()
+var hostBackoffs = newHostBackoff()
+
+// hostBackoff tracks, per host, exponential backoff windows set by 429/503
+// responses so one misbehaving feed doesn't get hammered every scheduler
+// tick.
+type hostBackoff struct {
+	mu       sync.Mutex
+	until    map[string]time.Time
+	failures map[string]int
 }
 
-type AtomLinkforCnn struct {
-	Href string `xml:"href,attr"`
+func newHostBackoff() *hostBackoff {
+	return &hostBackoff{until: map[string]time.Time{}, failures: map[string]int{}}
 }
 
-type Enclosure struct {
-	URL    string `xml:"url,attr"`
-	Type   string `xml:"type,attr"`
-	Length int    `xml:"length,attr"`
+func (b *hostBackoff) blocked(host string) (time.Time, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	until, ok := b.until[host]
+	return until, ok && time.Now().Before(until)
 }
 
-type GuidIsPermaLink struct {
-	URL     string `xml:",chardata"`
-	IsPerma bool   `xml:"isPermaLink,attr"`
-}
+func (b *hostBackoff) fail(host string, retryAfter time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
 
-type News struct {
-	Title         string    `bson:"title"`
-	Link          string    `bson:"link"`
-	Description   string    `bson:"description"`
-	PubDate       time.Time `bson:"pub_date"`
-	Category      []string  `bson:"category"`
-	Source        string    `bson:"source"`
-	Creator       string    `bson:"creator"`
-	Language      string    `bson:"language"`
-	LastBuildDate time.Time `bson:"last_build_date"`
-	ImageUrl      string    `bson:"imageUrl"`
-	SubCategory   string    `bson:"sub_category"`
-	Hash          string    `bson:"generatedHash"`
+	b.failures[host]++
+	wait := retryAfter
+	if wait <= 0 {
+		shift := b.failures[host]
+		if shift > 6 {
+			shift = 6
+		}
+		wait = time.Duration(1<<uint(shift)) * time.Second
+	}
+	b.until[host] = time.Now().Add(wait)
 }
 
-var client *mongo.Client
+func (b *hostBackoff) succeed(host string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.failures, host)
+	delete(b.until, host)
+}
 
 type RSSFeeds map[string]map[string][]string
 
@@ -139,36 +180,68 @@ func main() {
 	})
 
 	router.GET("/rssfetch", func(c *gin.Context) {
-		handlerfetchrss()
+		go runFetchCycle()
+		c.JSON(http.StatusAccepted, gin.H{"message": "RSS taraması başlatıldı"})
+	})
+
+	router.GET("/feeds/status", func(c *gin.Context) {
+		feedsStatusHandler(c)
 	})
 
+	router.POST("/discover", func(c *gin.Context) {
+		discoverHandler(c)
+	})
+
+	router.GET("/feeds/:category", func(c *gin.Context) {
+		feedsJSONHandler(c)
+	})
+
+	startFeedScheduler()
+
 	fmt.Println("Server çalışıyor: http://localhost:8080")
 	router.Run(":8080")
 }
 
-func handlerfetchrss() {
+// startFeedScheduler launches the background loop that keeps calling
+// runFetchCycle, so feeds get refreshed as their TTL/SkipHours/SkipDays come
+// due without anything external having to hit /rssfetch.
+func startFeedScheduler() {
+	ticker := time.NewTicker(schedulerInterval)
+	go func() {
+		for range ticker.C {
+			runFetchCycle()
+		}
+	}()
+}
 
+// runFetchCycle walks every configured feed and, for the ones that are due
+// (per their persisted FeedState), fetches them with conditional GET and
+// stores any new news items.
+func runFetchCycle() {
 	feedCollection := client.Database(dbName).Collection(collectionFeeds)
 	newsCollection := client.Database(dbName).Collection(collectionNews)
+	stateCollection := client.Database(dbName).Collection(collectionFeedState)
+
+	states := loadFeedStates(stateCollection)
 
 	cursor, err := feedCollection.Find(context.TODO(), bson.M{})
 	if err != nil {
-		log.Fatal(err)
+		log.Println("Feed listesi okunamadı:", err)
+		return
 	}
 	defer cursor.Close(context.TODO())
 
 	var wg sync.WaitGroup
-	jobs := make(chan struct {
-		url      string
-		category string
-	}, 100)
+	jobs := make(chan fetchJob, 100)
 
 	// Worker'ları başlat (Bu kısım sadece **1 kere** çağrılmalı)
 	for i := 0; i < workerCount; i++ {
 		wg.Add(1)
-		go worker(jobs, &wg, newsCollection)
+		go worker(jobs, &wg, newsCollection, stateCollection)
 	}
 
+	now := time.Now()
+
 	// RSS URL'lerini işle ve `jobs` kanalına ekle
 	for cursor.Next(context.TODO()) {
 		var rssFeed struct {
@@ -182,11 +255,14 @@ func handlerfetchrss() {
 
 		for _, urls := range rssFeed.Topics {
 			for _, url := range urls {
+				state := states[url]
+				if !state.NextFetchAt.IsZero() && state.NextFetchAt.After(now) {
+					fmt.Println("⏭️  Atlanıyor, süresi dolmadı:", url, "sıradaki:", state.NextFetchAt)
+					continue
+				}
+
 				fmt.Println("RSS Okunuyor:", url)
-				jobs <- struct {
-					url      string
-					category string
-				}{url, rssFeed.Category}
+				jobs <- fetchJob{url: url, category: rssFeed.Category, state: state}
 			}
 		}
 	}
@@ -198,25 +274,41 @@ func handlerfetchrss() {
 }
 
 // ✅ Worker fonksiyonu
-func worker(jobs <-chan struct {
-	url      string
-	category string
-}, wg *sync.WaitGroup, newsCollection *mongo.Collection) {
+func worker(jobs <-chan fetchJob, wg *sync.WaitGroup, newsCollection, stateCollection *mongo.Collection) {
 	defer wg.Done()
 
 	for job := range jobs {
-		channel, newsItems := fetchRSS(job.url, job.category)
+		f, newsItems, newState, err := fetchFeed(job.url, job.state)
+		saveFeedState(stateCollection, job.url, newState)
+
+		if err != nil {
+			log.Println("RSS çekilemedi:", job.url, err)
+			continue
+		}
+		if f == nil {
+			// 304 Not Modified: yeni haber yok, sadece zamanlama güncellendi.
+			continue
+		}
+
+		language := f.Language
 
 		for _, news := range newsItems {
 			news.Category = append(news.Category, job.category) // ✅ Kategori Doğru Ekleniyor
+			news.SubCategory = job.category
 			news.Source = job.url
-			if channel.Language == "" {
+			if language == "" {
 				news.Language = extractLanguageFromURL(news.Source)
 			} else {
-				news.Language = channel.Language
+				news.Language = language
 			}
 
-			filter := bson.M{"hash": news.Hash}
+			news.ClusterID = assignClusterID(newsCollection, news.SimHash, news.CanonicalURLHash)
+
+			orFilters := []bson.M{{"canonical_url_hash": news.CanonicalURLHash}}
+			if news.EntryID != "" {
+				orFilters = append(orFilters, bson.M{"entry_id": news.EntryID})
+			}
+			filter := bson.M{"$or": orFilters}
 			update := bson.M{"$setOnInsert": news}
 			opts := options.Update().SetUpsert(true)
 
@@ -232,278 +324,555 @@ func worker(jobs <-chan struct {
 	}
 }
 
-func fetchRSS(url, category string) (Channel, []News) {
-	resp, err := http.Get(url)
+// fetchFeed fetches rawURL honoring conditional-GET (If-None-Match /
+// If-Modified-Since from state), treats 304 as success with no new items,
+// and backs off per-host on 429/503 (respecting Retry-After when present).
+// It returns the updated FeedState to persist regardless of outcome.
+func fetchFeed(rawURL string, state FeedState) (*feed.Feed, []News, FeedState, error) {
+	host := hostOf(rawURL)
+
+	if until, blocked := hostBackoffs.blocked(host); blocked {
+		return nil, nil, state, fmt.Errorf("%s için backoff aktif, %s sonra tekrar denenecek", host, time.Until(until).Round(time.Second))
+	}
+
+	req, err := http.NewRequest(http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, nil, state, err
+	}
+	if state.ETag != "" {
+		req.Header.Set("If-None-Match", state.ETag)
+	}
+	if state.LastModified != "" {
+		req.Header.Set("If-Modified-Since", state.LastModified)
+	}
 
+	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
-		log.Println("RSS çekilemedi:", err)
-		return Channel{}, nil
+		return nil, nil, state, err
 	}
 	defer resp.Body.Close()
 
+	now := time.Now()
+
+	switch resp.StatusCode {
+	case http.StatusNotModified:
+		hostBackoffs.succeed(host)
+		state.LastFetchedAt = now
+		state.NextFetchAt = nextFetchAt(state.TTLMinutes, state.SkipHours, state.SkipDays, now)
+		return nil, nil, state, nil
+
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		retryAfter := parseRetryAfter(resp.Header.Get("Retry-After"))
+		hostBackoffs.fail(host, retryAfter)
+		return nil, nil, state, fmt.Errorf("%s: %s", resp.Status, rawURL)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		hostBackoffs.fail(host, 0)
+		return nil, nil, state, fmt.Errorf("beklenmeyen durum kodu %d: %s", resp.StatusCode, rawURL)
+	}
+
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		log.Println("RSS okunamadı:", err)
-		return Channel{}, nil
+		return nil, nil, state, err
 	}
 
-	var rss RSS
-	err = xml.Unmarshal(body, &rss)
+	f, err := feed.Parse(body)
 	if err != nil {
-		log.Println("RSS parse hatası:", err)
-		return Channel{}, nil
+		hostBackoffs.fail(host, 0)
+		return nil, nil, state, err
+	}
+	hostBackoffs.succeed(host)
+
+	state.ETag = resp.Header.Get("ETag")
+	state.LastModified = resp.Header.Get("Last-Modified")
+	state.LastFetchedAt = now
+	state.TTLMinutes = f.TTLMinutes
+	state.SkipHours = f.SkipHours
+	state.SkipDays = f.SkipDays
+	state.NextFetchAt = nextFetchAt(state.TTLMinutes, state.SkipHours, state.SkipDays, now)
+	if f.Image != "" {
+		state.Image = f.Image
 	}
 
+	return f, newsFromFeed(f, rawURL), state, nil
+}
+
+// newsFromFeed converts a normalized feed.Feed's items into the News
+// documents we persist, picking the best available link and media. source
+// is the feed URL they came from, used to scope entry_id so two unrelated
+// feeds that happen to reuse the same guid don't collide.
+func newsFromFeed(f *feed.Feed, source string) []News {
 	var newsItems []News
-	for _, item := range rss.Channel.Items {
 
-		var pubDate time.Time
-		var err error
+	for _, item := range f.Items {
 
-		if item.PubDate == "" {
+		pubDate := item.PubDate
+		if !item.HasPubDate {
 			fmt.Println("[WARNING] PubDate eksik, bugünün tarihi atanıyor:", item.Title)
 			pubDate = time.Now()
-		} else {
-			formats := []string{
-				time.RFC1123,
-				time.RFC1123Z,
-				time.RFC3339,
-				"Mon, 2 Jan 2006",
-			}
-
-			for _, format := range formats {
-				pubDate, err = time.Parse(format, item.PubDate)
-				if err == nil {
-					fmt.Println("[DEBUG] Kullanılan tarih formatı:", format)
-					break
-				}
-			}
-
-			if err != nil {
-				fmt.Println("[ERROR] Geçerli bir tarih formatı bulunamadı!", item.PubDate)
-				pubDate = time.Now() // Varsayılan tarih
-			}
 		}
 
-		descriptionText := cleanHTML(item.Description)
 		hash := GenerateHash(item.Title + item.Link)
 
 		var linkItem string
 
 		if isValidURL(item.Link) {
 			linkItem = item.Link
-		} else if item.AtomLinkforCNN != nil && isValidURL(item.AtomLinkforCNN.Href) { // 2️⃣ `<atom:link>` varsa al
-			linkItem = item.AtomLinkforCNN.Href
-		} else if item.GUID != nil && !item.GUID.IsPerma && isValidURL(item.GUID.URL) { // 3️⃣ Eğer GUID varsa ama perma değilse al
-			linkItem = item.GUID.URL
-		} else if isValidURL(item.AtomLink) {
+		} else if isValidURL(item.AtomLink) { // <atom:link href> varsa al (ör. CNN feed'leri)
 			linkItem = item.AtomLink
+		} else if item.GUID != "" && !item.IsPermaLink && isValidURL(item.GUID) { // GUID varsa ama perma değilse al
+			linkItem = item.GUID
 		} else {
 			log.Println("⚠️ Uyarı: Link eksik, haber atlanıyor:", item.Title)
 			continue
 		}
 
-		tm := time.Now()
+		attachments, newsImage := mediaExtractor.Extract(item.Media)
+		descriptionHTML := htmlSanitizer.Sanitize(item.Summary, linkItem)
 
-		newsImage := ""
+		entryID, _ := dedup.EntryID(source, item.GUID, item.IsPermaLink)
 
-		if item.MediaContent != nil && isValidURL(item.MediaContent.URL) {
-			newsImage = item.MediaContent.URL
-		}
+		newsItems = append(newsItems, News{
+			Title:            item.Title,
+			Link:             linkItem,
+			Description:      descriptionHTML,
+			Content:          htmlSanitizer.Sanitize(item.ContentHTML, linkItem),
+			PubDate:          pubDate,
+			ImageUrl:         newsImage,
+			Attachments:      attachments,
+			Category:         item.Tags,
+			Hash:             hash,
+			LastBuildDate:    time.Now(),
+			Creator:          item.Author,
+			CanonicalURLHash: dedup.URLHash(linkItem),
+			EntryID:          entryID,
+			SimHash:          int64(dedup.SimHash(plainText(descriptionHTML))),
+		})
 
-		if newsImage == "" && item.MediaThumbnail != nil && isValidURL(item.MediaThumbnail.URL) {
-			newsImage = item.MediaThumbnail.URL
-		}
-		if item.MediaContentfor != nil && isValidURL(item.MediaContentfor.URL) {
-			newsImage = item.MediaContentfor.URL
+	}
+
+	return newsItems
+}
+
+// nextFetchAt computes when a feed should be fetched again, honoring its
+// <ttl> plus <skipHours>/<skipDays> when present. On a 304 there's no fresh
+// body to read these from, so callers pass the values persisted in
+// FeedState from the last successful parse instead of zero values, or the
+// feed would permanently degrade to defaultFetchInterval once it settles
+// into returning 304s.
+func nextFetchAt(ttlMinutes int, skipHours []int, skipDays []string, now time.Time) time.Time {
+	interval := defaultFetchInterval
+	if ttlMinutes > 0 {
+		interval = time.Duration(ttlMinutes) * time.Minute
+	}
+
+	next := now.Add(interval)
+	for i := 0; i < 48 && (containsInt(skipHours, next.Hour()) || containsString(skipDays, next.Weekday().String())); i++ {
+		next = next.Add(time.Hour)
+	}
+	return next
+}
+
+func containsInt(values []int, v int) bool {
+	for _, x := range values {
+		if x == v {
+			return true
 		}
+	}
+	return false
+}
 
-		if newsImage == "" && item.MediaThumbnailfor != nil && isValidURL(item.MediaThumbnailfor.URL) {
-			newsImage = item.MediaThumbnailfor.URL
+func containsString(values []string, v string) bool {
+	for _, x := range values {
+		if strings.EqualFold(x, v) {
+			return true
 		}
+	}
+	return false
+}
+
+// parseRetryAfter understands both forms of the Retry-After header: a
+// number of seconds, or an HTTP date.
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return time.Until(at)
+	}
+	return 0
+}
+
+func hostOf(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	return u.Host
+}
+
+func loadFeedStates(stateCollection *mongo.Collection) map[string]FeedState {
+	states := map[string]FeedState{}
 
-		if newsImage == "" && isValidURL(item.Image) {
-			newsImage = item.Image
+	cursor, err := stateCollection.Find(context.TODO(), bson.M{})
+	if err != nil {
+		log.Println("Feed durumları okunamadı:", err)
+		return states
+	}
+	defer cursor.Close(context.TODO())
+
+	for cursor.Next(context.TODO()) {
+		var state FeedState
+		if err := cursor.Decode(&state); err != nil {
+			continue
 		}
+		states[state.URL] = state
+	}
+
+	return states
+}
+
+// assignClusterID groups a news item with any recent story whose SimHash is
+// within simHashClusterThreshold bits, so the same wire story picked up by
+// several outlets shares one cluster_id. When nothing close enough is
+// found, the item's own canonical URL hash starts a new cluster.
+func assignClusterID(newsCollection *mongo.Collection, simhash int64, ownHash string) string {
+	if simhash == 0 {
+		return ownHash
+	}
+
+	cursor, err := newsCollection.Find(
+		context.TODO(),
+		bson.M{"simhash": bson.M{"$ne": 0}},
+		options.Find().SetSort(bson.M{"pub_date": -1}).SetLimit(simHashClusterScanLimit),
+	)
+	if err != nil {
+		log.Println("Küme taraması başarısız:", err)
+		return ownHash
+	}
+	defer cursor.Close(context.TODO())
 
-		if newsImage == "" && item.MediaContent != nil {
-			fmt.Println("MediaContent URL bulundu:", item.MediaContent.URL)
-			newsImage = item.MediaContent.URL
+	for cursor.Next(context.TODO()) {
+		var candidate struct {
+			SimHash   int64  `bson:"simhash"`
+			ClusterID string `bson:"cluster_id"`
 		}
-		if newsImage == "" && item.MediaContent != nil {
-			fmt.Println("MediaContent URL bulundu:", item.MediaContent.URL)
-			newsImage = item.MediaContent.URL2
+		if err := cursor.Decode(&candidate); err != nil || candidate.ClusterID == "" {
+			continue
 		}
-
-		if newsImage == "" && item.FigureImage != "" {
-			newsImage = item.FigureImage
+		if dedup.HammingDistance(uint64(simhash), uint64(candidate.SimHash)) <= simHashClusterThreshold {
+			return candidate.ClusterID
 		}
+	}
 
-		if newsImage == "" && item.Description != "" {
-			doc, err := html.Parse(strings.NewReader(item.Description))
-			if err == nil {
-				newsImage = extractImageURL(doc)
-			}
+	return ownHash
+}
+
+func saveFeedState(stateCollection *mongo.Collection, url string, state FeedState) {
+	state.URL = url
+	filter := bson.M{"url": url}
+	update := bson.M{"$set": state}
+	opts := options.Update().SetUpsert(true)
+
+	if _, err := stateCollection.UpdateOne(context.TODO(), filter, update, opts); err != nil {
+		log.Println("Feed durumu kaydedilemedi:", url, err)
+	}
+}
+
+// feedsStatusHandler reports when every known feed was last fetched and
+// when it's next due, so operators can see the scheduler's state.
+func feedsStatusHandler(c *gin.Context) {
+	stateCollection := client.Database(dbName).Collection(collectionFeedState)
+	states := loadFeedStates(stateCollection)
+
+	type feedStatus struct {
+		URL           string    `json:"url"`
+		LastFetchedAt time.Time `json:"last_fetched_at"`
+		NextFetchAt   time.Time `json:"next_fetch_at"`
+	}
+
+	statuses := make([]feedStatus, 0, len(states))
+	for _, state := range states {
+		statuses = append(statuses, feedStatus{
+			URL:           state.URL,
+			LastFetchedAt: state.LastFetchedAt,
+			NextFetchAt:   state.NextFetchAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{"feeds": statuses})
+}
+
+// discoveryHTTPClient fetches URLs supplied by the caller of /discover: the
+// candidate's homepage and every feed candidate found on it. Without the
+// timeout and dial guard below, an attacker could point req.URL at an
+// internal service or the cloud metadata endpoint (169.254.169.254) and have
+// this server fetch it on their behalf.
+var discoveryHTTPClient = &http.Client{
+	Timeout: 10 * time.Second,
+	Transport: &http.Transport{
+		DialContext: safeDialContext,
+	},
+}
+
+// safeDialContext resolves addr and refuses to connect if any resolved IP is
+// loopback, link-local or otherwise private, so discoveryHTTPClient can't be
+// used to reach internal-only destinations.
+func safeDialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	ips, err := net.DefaultResolver.LookupIP(ctx, "ip", host)
+	if err != nil {
+		return nil, err
+	}
+	for _, ip := range ips {
+		if isDisallowedDiscoveryIP(ip) {
+			return nil, fmt.Errorf("discovery: %s adresine erişim engellendi", ip)
 		}
+	}
+
+	return (&net.Dialer{}).DialContext(ctx, network, net.JoinHostPort(ips[0].String(), port))
+}
+
+// isDisallowedDiscoveryIP reports whether ip is a loopback, link-local or
+// private address that /discover should never be able to reach.
+func isDisallowedDiscoveryIP(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsUnspecified() || ip.IsPrivate() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast()
+}
+
+type discoverRequest struct {
+	URL      string `json:"url" binding:"required"`
+	Category string `json:"category"`
+}
+
+type discoveredFeed struct {
+	URL       string `json:"url"`
+	Title     string `json:"title"`
+	Language  string `json:"language"`
+	ItemCount int    `json:"item_count"`
+}
+
+// discoverHandler fetches req.URL's homepage, looks for advertised feed
+// <link> tags and probes the common feed paths, validates every candidate
+// by actually parsing it, and optionally registers the working ones under
+// Category in rss_feeds.
+func discoverHandler(c *gin.Context) {
+	var req discoverRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "geçersiz istek: url zorunlu"})
+		return
+	}
+
+	resp, err := discoveryHTTPClient.Get(req.URL)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": "sayfa alınamadı: " + err.Error()})
+		return
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": "sayfa okunamadı: " + err.Error()})
+		return
+	}
 
-		if newsImage == "" && item.Enclosure != nil {
-			fmt.Println("Enclosure bulundu:", item.Enclosure.URL)
-			newsImage = item.Enclosure.URL
+	candidates := append(discovery.LinkTags(string(body), req.URL), discovery.ProbeURLs(req.URL)...)
+
+	seen := map[string]bool{}
+	var found []discoveredFeed
+
+	for _, candidate := range candidates {
+		if candidate.URL == "" || seen[candidate.URL] {
+			continue
 		}
+		seen[candidate.URL] = true
 
-		if newsImage == "" && item.IpImage != "" {
-			fmt.Println("IpImage bulundu:", item.IpImage)
-			newsImage = item.IpImage
+		f, err := validateFeedURL(candidate.URL)
+		if err != nil {
+			continue
 		}
 
-		newsItems = append(newsItems, News{
-			Title:         item.Title,
-			Link:          linkItem,
-			Description:   descriptionText,
-			PubDate:       pubDate,
-			ImageUrl:      newsImage,
-			Hash:          hash,
-			SubCategory:   category,
-			LastBuildDate: tm,
-			Creator:       item.Creator,
+		found = append(found, discoveredFeed{
+			URL:       candidate.URL,
+			Title:     f.Title,
+			Language:  f.Language,
+			ItemCount: len(f.Items),
 		})
+	}
 
+	if req.Category != "" {
+		for _, discovered := range found {
+			upsertDiscoveredFeed(req.Category, discovered.URL)
+		}
 	}
 
-	return rss.Channel, newsItems
+	c.JSON(http.StatusOK, gin.H{"feeds": found})
 }
 
-func cleanHTML(htmlString string) string {
+// validateFeedURL fetches candidateURL and confirms it actually parses as a
+// feed before it's reported back as a discovery result.
+func validateFeedURL(candidateURL string) (*feed.Feed, error) {
+	resp, err := discoveryHTTPClient.Get(candidateURL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("beklenmeyen durum kodu %d", resp.StatusCode)
+	}
 
-	doc, err := goquery.NewDocumentFromReader(strings.NewReader(htmlString))
+	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		log.Println("HTML parse hatası:", err)
-		return htmlString
+		return nil, err
 	}
 
-	return doc.Text()
+	return feed.Parse(body)
 }
 
-func isValidURL(url string) bool {
-	return strings.HasPrefix(url, "http")
+// upsertDiscoveredFeed registers a feed URL found by /discover under the
+// caller-supplied category, alongside whatever topics were added manually.
+func upsertDiscoveredFeed(category, feedURL string) {
+	feedCollection := client.Database(dbName).Collection(collectionFeeds)
+	filter := bson.M{"category": category}
+	update := bson.M{"$addToSet": bson.M{"topics.discovered": feedURL}}
+	opts := options.Update().SetUpsert(true)
+
+	if _, err := feedCollection.UpdateOne(context.TODO(), filter, update, opts); err != nil {
+		log.Println("Keşfedilen feed kaydedilemedi:", err)
+	}
 }
 
-func extractImageURL(n *html.Node) string {
-	var imageURL string
+// feedsPageSize bounds how many items /feeds/:category.json returns per
+// page; callers page further with ?page=N, following next_url.
+const feedsPageSize = 20
+
+// feedsJSONHandler emits a category's stored news as a JSON Feed 1.1
+// document, so the service itself can be re-syndicated from the same way
+// it ingests from everyone else.
+func feedsJSONHandler(c *gin.Context) {
+	raw := c.Param("category")
+	if !strings.HasSuffix(raw, ".json") {
+		c.JSON(http.StatusNotFound, gin.H{"error": "bulunamadı"})
+		return
+	}
+	category := strings.TrimSuffix(raw, ".json")
 
-	if n.Type == html.ElementNode {
-		switch n.Data {
-		case "img":
-			for _, attr := range n.Attr {
-				if attr.Key == "src" {
-					imageURL = attr.Val
-				}
-			}
-		case "media:content":
-			for _, attr := range n.Attr {
-				if attr.Key == "url" {
-					imageURL = attr.Val
-				}
-			}
-		case "enclosure":
-			for _, attr := range n.Attr {
-				if attr.Key == "url" {
-					imageURL = attr.Val
-				}
-			}
-		case "image":
-			for c := n.FirstChild; c != nil; c = c.NextSibling {
-				if c.Type == html.ElementNode && c.Data == "url" && c.FirstChild != nil {
-					imageURL = c.FirstChild.Data
-				}
-			}
-		}
+	page, err := strconv.Atoi(c.Query("page"))
+	if err != nil || page < 1 {
+		page = 1
 	}
 
-	if n.Type == html.ElementNode && n.Data == "enclosure" {
-		for _, attr := range n.Attr {
-			if attr.Key == "img" {
-				imageURL = attr.Val
+	newsCollection := client.Database(dbName).Collection(collectionNews)
 
-			}
-			if attr.Key == "image" {
-				imageURL = attr.Val
+	cursor, err := newsCollection.Find(
+		context.TODO(),
+		bson.M{"sub_category": category},
+		options.Find().
+			SetSort(bson.M{"pub_date": -1}).
+			SetSkip(int64((page-1)*feedsPageSize)).
+			SetLimit(int64(feedsPageSize+1)),
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "haberler okunamadı"})
+		return
+	}
+	defer cursor.Close(context.TODO())
 
-			}
-			if attr.Key == "url" {
-				imageURL = attr.Val
-			}
+	var items []News
+	for cursor.Next(context.TODO()) {
+		var news News
+		if err := cursor.Decode(&news); err != nil {
+			continue
 		}
+		items = append(items, news)
 	}
 
-	if n.Type == html.ElementNode && n.Data == "media:content" {
-		for _, attr := range n.Attr {
-			if attr.Key == "url" {
-				imageURL = attr.Val
-
-			}
-			if attr.Key == "img" {
-				imageURL = attr.Val
+	hasNext := len(items) > feedsPageSize
+	if hasNext {
+		items = items[:feedsPageSize]
+	}
 
-			}
-			if attr.Key == "image" {
-				imageURL = attr.Val
+	icon := categoryIcon(category)
 
-			}
-		}
+	doc := feed.JSONFeedDocument{
+		Version: feed.JSONFeedVersion,
+		Title:   category,
+		Icon:    icon,
+		Favicon: icon,
+		Items:   make([]feed.JSONFeedItem, 0, len(items)),
 	}
 
-	if n.Type == html.ElementNode && n.Data == "enclosure" {
-		for _, attr := range n.Attr {
-			if attr.Key == "url" {
-				return attr.Val
-			}
+	for _, news := range items {
+		id := news.EntryID
+		if id == "" {
+			id = news.Hash
 		}
-	}
-
-	if n.Type == html.ElementNode && n.Data == "media:thumbnail" {
-		for _, attr := range n.Attr {
-			if attr.Key == "url" {
-				imageURL = attr.Val
 
-			}
-			if attr.Key == "img" {
-				imageURL = attr.Val
+		var authors []feed.JSONFeedAuthor
+		if news.Creator != "" {
+			authors = []feed.JSONFeedAuthor{{Name: news.Creator}}
+		}
 
-			}
-			if attr.Key == "image" {
-				imageURL = attr.Val
-			}
+		var attachments []feed.JSONFeedAttachment
+		for _, a := range news.Attachments {
+			attachments = append(attachments, feed.JSONFeedAttachment{URL: a.URL, MimeType: a.MimeType})
 		}
+
+		doc.Items = append(doc.Items, feed.JSONFeedItem{
+			ID:            id,
+			URL:           news.Link,
+			Title:         news.Title,
+			ContentHTML:   news.Content,
+			Summary:       news.Description,
+			DatePublished: news.PubDate.Format(time.RFC3339),
+			Authors:       authors,
+			Tags:          news.Category,
+			Attachments:   attachments,
+		})
 	}
 
-	if n.Type == html.ElementNode && n.Data == "image" {
-		for _, attr := range n.Attr {
-			if attr.Key == "url" {
-				imageURL = attr.Val
-			}
-			if attr.Key == "img" {
-				imageURL = attr.Val
-			}
-			if attr.Key == "image" {
-				imageURL = attr.Val
-			}
-		}
+	if hasNext {
+		doc.NextURL = fmt.Sprintf("/feeds/%s.json?page=%d", category, page+1)
 	}
 
-	if n.Type == html.ElementNode && (n.Data == "description" || n.Data == "content:encoded") {
-		if strings.Contains(n.FirstChild.Data, "http") {
-			imageURL = extractURLFromText(n.FirstChild.Data)
-		}
+	c.JSON(http.StatusOK, doc)
+}
+
+// categoryIcon returns the first non-empty channel image recorded for any
+// feed registered under category, used as the JSON Feed's icon/favicon.
+func categoryIcon(category string) string {
+	feedCollection := client.Database(dbName).Collection(collectionFeeds)
+
+	var rssFeed struct {
+		Topics map[string][]string `bson:"topics"`
+	}
+	if err := feedCollection.FindOne(context.TODO(), bson.M{"category": category}).Decode(&rssFeed); err != nil {
+		return ""
 	}
 
-	for c := n.FirstChild; c != nil; c = c.NextSibling {
-		if imgURL := extractImageURL(c); imgURL != "" {
-			imageURL = imgURL
+	stateCollection := client.Database(dbName).Collection(collectionFeedState)
+	states := loadFeedStates(stateCollection)
+
+	for _, urls := range rssFeed.Topics {
+		for _, url := range urls {
+			if state, ok := states[url]; ok && state.Image != "" {
+				return state.Image
+			}
 		}
 	}
+	return ""
+}
 
-	return imageURL
+func isValidURL(url string) bool {
+	return strings.HasPrefix(url, "http")
 }
 
 func extractLanguageFromURL(url string) string {
@@ -519,19 +888,6 @@ func extractLanguageFromURL(url string) string {
 	return "tr"
 }
 
-func extractURLFromText(text string) string {
-	// Burada basit bir HTTP URL bulma mekanizması kullanabiliriz
-	start := strings.Index(text, "http")
-	if start == -1 {
-		return ""
-	}
-	end := strings.Index(text[start:], " ")
-	if end == -1 {
-		end = len(text)
-	}
-	return text[start : start+end]
-}
-
 func ExtractText(n *html.Node) string {
 	var result string
 	if n.Type == html.TextNode {
@@ -545,6 +901,20 @@ func ExtractText(n *html.Node) string {
 	return result
 }
 
+// plainText strips rawHTML down to its text content, so callers that need
+// to compare/hash the actual wording (e.g. dedup.SimHash) aren't thrown off
+// by markup and href URLs that vary across outlets even for the same story.
+func plainText(rawHTML string) string {
+	if strings.TrimSpace(rawHTML) == "" {
+		return ""
+	}
+	doc, err := html.Parse(strings.NewReader(rawHTML))
+	if err != nil {
+		return rawHTML
+	}
+	return ExtractText(doc)
+}
+
 func GenerateHash(title string) string {
 	hash := sha256.Sum256([]byte(title))
 	return hex.EncodeToString(hash[:])
@@ -0,0 +1,285 @@
+// Package media parses the media elements that can show up inside an RSS
+// or Atom item (Yahoo MRSS, <enclosure>, iTunes podcast tags) and picks the
+// best attachment/thumbnail out of whatever the feed happened to publish.
+package media
+
+import (
+	"encoding/xml"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Medium is the MRSS "medium" attribute, normalized to a small closed set.
+type Medium string
+
+const (
+	MediumImage    Medium = "image"
+	MediumAudio    Medium = "audio"
+	MediumVideo    Medium = "video"
+	MediumDocument Medium = "document"
+	// MediumOther covers attachments whose medium/MIME type doesn't match
+	// any of the above (torrents, archives, arbitrary octet streams), so
+	// they aren't silently mislabeled as images.
+	MediumOther Medium = "other"
+)
+
+// Attachment is a single piece of media attached to a news item, regardless
+// of whether it arrived as media:content, media:thumbnail, <enclosure> or an
+// iTunes podcast tag.
+type Attachment struct {
+	URL       string `bson:"url"`
+	MimeType  string `bson:"mime_type,omitempty"`
+	Size      int64  `bson:"size,omitempty"`
+	Width     int    `bson:"width,omitempty"`
+	Height    int    `bson:"height,omitempty"`
+	Medium    Medium `bson:"medium,omitempty"`
+	IsTorrent bool   `bson:"is_torrent,omitempty"`
+}
+
+// MediaContent is a single <media:content> element, including the children
+// MRSS allows to hang off of it.
+type MediaContent struct {
+	XMLName     xml.Name         `xml:"http://search.yahoo.com/mrss/ content"`
+	URL         string           `xml:"url,attr"`
+	Type        string           `xml:"type,attr"`
+	Medium      string           `xml:"medium,attr"`
+	Width       int              `xml:"width,attr"`
+	Height      int              `xml:"height,attr"`
+	FileSize    int64            `xml:"fileSize,attr"`
+	Expression  string           `xml:"expression,attr"`
+	IsDefault   bool             `xml:"isDefault,attr"`
+	Thumbnails  []MediaThumbnail `xml:"http://search.yahoo.com/mrss/ thumbnail"`
+	Description MediaDescription `xml:"http://search.yahoo.com/mrss/ description"`
+	Credits     []MediaCredit    `xml:"http://search.yahoo.com/mrss/ credit"`
+	PeerLink    *MediaPeerLink   `xml:"http://search.yahoo.com/mrss/ peerLink"`
+}
+
+// MediaThumbnail is a <media:thumbnail>.
+type MediaThumbnail struct {
+	URL    string `xml:"url,attr"`
+	Width  int    `xml:"width,attr"`
+	Height int    `xml:"height,attr"`
+}
+
+// MediaDescription is the optional <media:description> of a content/group.
+type MediaDescription struct {
+	Type string `xml:"type,attr"`
+	Text string `xml:",chardata"`
+}
+
+// MediaCredit is a <media:credit>, e.g. the photographer or agency.
+type MediaCredit struct {
+	Role string `xml:"role,attr"`
+	Text string `xml:",chardata"`
+}
+
+// MediaPeerLink is a <media:peerLink>, typically used for torrents.
+type MediaPeerLink struct {
+	Type string `xml:"type,attr"`
+	Href string `xml:"href,attr"`
+}
+
+// MediaGroup is a <media:group>, which bundles several versions/renditions
+// of the same piece of media (e.g. different resolutions of a video).
+type MediaGroup struct {
+	XMLName    xml.Name         `xml:"http://search.yahoo.com/mrss/ group"`
+	Contents   []MediaContent   `xml:"http://search.yahoo.com/mrss/ content"`
+	Thumbnails []MediaThumbnail `xml:"http://search.yahoo.com/mrss/ thumbnail"`
+}
+
+// Enclosure is the RSS 2.0 <enclosure> element.
+type Enclosure struct {
+	URL    string `xml:"url,attr"`
+	Type   string `xml:"type,attr"`
+	Length int64  `xml:"length,attr"`
+}
+
+// ItunesImage is the iTunes podcast <itunes:image> element.
+type ItunesImage struct {
+	Href string `xml:"href,attr"`
+}
+
+// Source bundles every place media can come from on a single feed item.
+// Extractor.Extract reads whichever fields are populated; zero values are
+// skipped.
+type Source struct {
+	Group       *MediaGroup
+	Contents    []MediaContent
+	Thumbnails  []MediaThumbnail
+	Enclosure   *Enclosure
+	ItunesImage *ItunesImage
+	// HTML is the item's description/content:encoded, used as a last
+	// resort to scrape an <img> or og:image when nothing else yielded one.
+	HTML string
+}
+
+// Extractor turns a media Source into the Attachments we persist, plus the
+// single best thumbnail URL to show in list views.
+type Extractor struct{}
+
+// NewExtractor returns a ready-to-use Extractor. It holds no state today but
+// is a struct (rather than free functions) so extraction rules can grow
+// configuration later without changing every call site.
+func NewExtractor() *Extractor {
+	return &Extractor{}
+}
+
+// Extract collects every attachment found in src and returns them alongside
+// the best thumbnail URL for quick display.
+func (e *Extractor) Extract(src Source) (attachments []Attachment, thumbnail string) {
+	var contents []MediaContent
+	contents = append(contents, src.Contents...)
+	var thumbs []MediaThumbnail
+	thumbs = append(thumbs, src.Thumbnails...)
+
+	if src.Group != nil {
+		contents = append(contents, src.Group.Contents...)
+		thumbs = append(thumbs, src.Group.Thumbnails...)
+	}
+
+	for _, c := range contents {
+		if c.URL == "" {
+			continue
+		}
+		attachments = append(attachments, Attachment{
+			URL:      c.URL,
+			MimeType: c.Type,
+			Size:     c.FileSize,
+			Width:    c.Width,
+			Height:   c.Height,
+			Medium:   mediumOf(c.Medium, c.Type),
+		})
+		for _, t := range c.Thumbnails {
+			thumbs = append(thumbs, t)
+		}
+	}
+
+	for _, t := range thumbs {
+		if t.URL == "" {
+			continue
+		}
+		attachments = append(attachments, Attachment{
+			URL:    t.URL,
+			Width:  t.Width,
+			Height: t.Height,
+			Medium: MediumImage,
+		})
+	}
+
+	if src.Enclosure != nil && src.Enclosure.URL != "" {
+		attachments = append(attachments, Attachment{
+			URL:       src.Enclosure.URL,
+			MimeType:  src.Enclosure.Type,
+			Size:      src.Enclosure.Length,
+			Medium:    mediumOf("", src.Enclosure.Type),
+			IsTorrent: strings.HasSuffix(strings.ToLower(src.Enclosure.URL), ".torrent"),
+		})
+	}
+
+	if src.ItunesImage != nil && src.ItunesImage.Href != "" {
+		attachments = append(attachments, Attachment{URL: src.ItunesImage.Href, Medium: MediumImage})
+	}
+
+	thumbnail = bestThumbnail(contents, thumbs)
+	if thumbnail == "" {
+		thumbnail = firstImage(attachments)
+	}
+	if thumbnail == "" && src.HTML != "" {
+		thumbnail = scrapeImage(src.HTML)
+	}
+
+	return attachments, thumbnail
+}
+
+// bestThumbnail prefers an explicit isDefault media:content, then the
+// content whose expression is "full" or "default", then the largest
+// media:thumbnail.
+func bestThumbnail(contents []MediaContent, thumbs []MediaThumbnail) string {
+	for _, c := range contents {
+		if c.IsDefault && isImageish(c.Medium, c.Type) && c.URL != "" {
+			return c.URL
+		}
+	}
+	for _, c := range contents {
+		if (c.Expression == "full" || c.Expression == "default" || c.Expression == "") && isImageish(c.Medium, c.Type) && c.URL != "" {
+			return c.URL
+		}
+	}
+
+	var best MediaThumbnail
+	for _, t := range thumbs {
+		if t.URL == "" {
+			continue
+		}
+		if t.Width*t.Height > best.Width*best.Height {
+			best = t
+		}
+	}
+	return best.URL
+}
+
+func firstImage(attachments []Attachment) string {
+	for _, a := range attachments {
+		if a.Medium == MediumImage {
+			return a.URL
+		}
+	}
+	return ""
+}
+
+func isImageish(medium, mimeType string) bool {
+	if medium == "" && mimeType == "" {
+		// MRSS allows omitting both; treat as image since that's the
+		// overwhelmingly common case for news thumbnails.
+		return true
+	}
+	return mediumOf(medium, mimeType) == MediumImage
+}
+
+// mediumOf normalizes the MRSS "medium" attribute, falling back to the
+// content's MIME type when medium is absent, which is common in the wild.
+func mediumOf(medium, mimeType string) Medium {
+	switch strings.ToLower(medium) {
+	case "image":
+		return MediumImage
+	case "audio":
+		return MediumAudio
+	case "video":
+		return MediumVideo
+	case "document":
+		return MediumDocument
+	}
+
+	switch {
+	case strings.HasPrefix(mimeType, "image/"):
+		return MediumImage
+	case strings.HasPrefix(mimeType, "audio/"):
+		return MediumAudio
+	case strings.HasPrefix(mimeType, "video/"):
+		return MediumVideo
+	case mimeType == "application/pdf":
+		return MediumDocument
+	}
+	return MediumOther
+}
+
+// scrapeImage falls back to pulling the first <img src> or og:image meta tag
+// out of raw HTML (typically the item's description or content:encoded)
+// when no structured media element gave us anything.
+func scrapeImage(rawHTML string) string {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(rawHTML))
+	if err != nil {
+		return ""
+	}
+
+	if og, ok := doc.Find(`meta[property="og:image"]`).Attr("content"); ok && og != "" {
+		return og
+	}
+
+	if src, ok := doc.Find("img").First().Attr("src"); ok {
+		return src
+	}
+
+	return ""
+}
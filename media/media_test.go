@@ -0,0 +1,47 @@
+package media
+
+import "testing"
+
+func TestExtractPrefersDefaultMediaContentThumbnail(t *testing.T) {
+	src := Source{
+		Contents: []MediaContent{
+			{URL: "https://example.com/small.jpg", Medium: "image", Expression: "sample"},
+			{URL: "https://example.com/default.jpg", Medium: "image", IsDefault: true},
+		},
+	}
+
+	_, thumbnail := NewExtractor().Extract(src)
+	if thumbnail != "https://example.com/default.jpg" {
+		t.Fatalf("expected isDefault content to win, got %q", thumbnail)
+	}
+}
+
+func TestExtractFallsBackToScrapedImage(t *testing.T) {
+	src := Source{HTML: `<p>no structured media</p><img src="https://example.com/scraped.jpg">`}
+
+	_, thumbnail := NewExtractor().Extract(src)
+	if thumbnail != "https://example.com/scraped.jpg" {
+		t.Fatalf("expected scraped <img> src as last-resort thumbnail, got %q", thumbnail)
+	}
+}
+
+func TestExtractMarksTorrentEnclosures(t *testing.T) {
+	src := Source{Enclosure: &Enclosure{URL: "https://example.com/movie.torrent", Type: "application/x-bittorrent"}}
+
+	attachments, _ := NewExtractor().Extract(src)
+	if len(attachments) != 1 || !attachments[0].IsTorrent {
+		t.Fatalf("expected a single torrent-flagged attachment, got %+v", attachments)
+	}
+}
+
+func TestMediumOfFallsBackToOtherForUnknownMimeTypes(t *testing.T) {
+	if got := mediumOf("", "application/x-bittorrent"); got != MediumOther {
+		t.Fatalf("expected unrecognized mime type to fall back to MediumOther, got %q", got)
+	}
+	if got := mediumOf("", "application/pdf"); got != MediumDocument {
+		t.Fatalf("expected application/pdf to map to MediumDocument, got %q", got)
+	}
+	if got := mediumOf("video", ""); got != MediumVideo {
+		t.Fatalf("expected explicit medium attribute to take precedence, got %q", got)
+	}
+}
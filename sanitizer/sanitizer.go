@@ -0,0 +1,233 @@
+// Package sanitizer strips untrusted feed HTML down to a safe, whitelisted
+// subset instead of dropping all markup the way a plain goquery.Text() call
+// does, so downstream consumers can still render paragraphs, links, lists
+// and images.
+package sanitizer
+
+import (
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// allowedTags is the whitelist of elements kept in sanitized output;
+// everything else is unwrapped (its children are kept, the tag is not),
+// except droppedTags, which are removed together with their children.
+var allowedTags = map[string]bool{
+	"p": true, "br": true, "strong": true, "em": true, "b": true, "i": true,
+	"a": true, "img": true, "ul": true, "ol": true, "li": true,
+	"blockquote": true, "pre": true, "code": true,
+	"h1": true, "h2": true, "h3": true, "h4": true,
+	"figure": true, "figcaption": true, "iframe": true,
+}
+
+// allowedAttrs is the whitelist of attributes kept per element.
+var allowedAttrs = map[string]map[string]bool{
+	"a":      {"href": true},
+	"img":    {"src": true, "alt": true},
+	"iframe": {"src": true},
+}
+
+// trustedVideoHosts is the whitelist of hosts allowed inside <iframe src>;
+// every other iframe (ad embeds, trackers) is dropped outright.
+var trustedVideoHosts = map[string]bool{
+	"www.youtube.com":  true,
+	"youtube.com":      true,
+	"player.vimeo.com": true,
+}
+
+var allowedSchemes = map[string]bool{"http": true, "https": true, "mailto": true}
+
+// Sanitizer cleans untrusted HTML fragments, rewriting relative URLs
+// against the item's own link along the way.
+type Sanitizer struct{}
+
+// New returns a ready-to-use Sanitizer.
+func New() *Sanitizer {
+	return &Sanitizer{}
+}
+
+// Sanitize strips rawHTML down to the tag/attribute whitelist, resolves
+// relative hrefs/srcs against baseLink, marks external links
+// rel="noopener nofollow", and drops tracking pixels and untrusted
+// iframes.
+func (s *Sanitizer) Sanitize(rawHTML, baseLink string) string {
+	if strings.TrimSpace(rawHTML) == "" {
+		return ""
+	}
+
+	context := &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body}
+	nodes, err := html.ParseFragment(strings.NewReader(rawHTML), context)
+	if err != nil {
+		return ""
+	}
+
+	base, _ := url.Parse(baseLink)
+
+	var clean []*html.Node
+	for _, n := range nodes {
+		clean = append(clean, s.sanitizeNode(n, base)...)
+	}
+
+	var sb strings.Builder
+	for _, n := range clean {
+		html.Render(&sb, n)
+	}
+	return sb.String()
+}
+
+// droppedTags are removed outright, along with their children: their
+// content is raw script/style source rather than reader content, so
+// unwrapping them (the treatment every other disallowed tag gets) would
+// dump that source into the sanitized output as visible text.
+var droppedTags = map[string]bool{
+	"script":   true,
+	"style":    true,
+	"noscript": true,
+}
+
+// sanitizeNode returns the replacement node(s) for n: itself (cleaned) when
+// it's a whitelisted element, its cleaned children when it's a disallowed
+// element (unwrapped rather than dropped), or nothing when it must be
+// removed outright along with its children (comments, tracking pixels,
+// untrusted iframes, script/style/noscript).
+func (s *Sanitizer) sanitizeNode(n *html.Node, base *url.URL) []*html.Node {
+	switch n.Type {
+	case html.TextNode:
+		return []*html.Node{n}
+	case html.ElementNode:
+		// handled below
+	default:
+		return nil
+	}
+
+	if droppedTags[n.Data] {
+		return nil
+	}
+
+	var children []*html.Node
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		children = append(children, s.sanitizeNode(c, base)...)
+	}
+
+	if !allowedTags[n.Data] {
+		return children
+	}
+	if n.Data == "img" && isTrackingPixel(n) {
+		return nil
+	}
+	if n.Data == "iframe" && !isTrustedVideoEmbed(n) {
+		return nil
+	}
+
+	cleaned := &html.Node{Type: html.ElementNode, Data: n.Data, DataAtom: n.DataAtom}
+	cleaned.Attr = s.cleanAttrs(n, base)
+	for _, c := range children {
+		cleaned.AppendChild(detach(c))
+	}
+
+	return []*html.Node{cleaned}
+}
+
+// cleanAttrs keeps only whitelisted attributes, resolves href/src against
+// base, drops disallowed protocols, and injects rel="noopener nofollow" on
+// anchors pointing off-site.
+func (s *Sanitizer) cleanAttrs(n *html.Node, base *url.URL) []html.Attribute {
+	allowed := allowedAttrs[n.Data]
+
+	var attrs []html.Attribute
+	for _, a := range n.Attr {
+		if !allowed[a.Key] {
+			continue
+		}
+		if a.Key == "href" || a.Key == "src" {
+			resolved, ok := resolveURL(a.Val, base)
+			if !ok {
+				continue
+			}
+			a.Val = resolved
+		}
+		attrs = append(attrs, a)
+	}
+
+	if n.Data == "a" {
+		if href := attrVal(attrs, "href"); href != "" && isExternal(href, base) {
+			attrs = append(attrs, html.Attribute{Key: "rel", Val: "noopener nofollow"})
+		}
+	}
+
+	return attrs
+}
+
+// resolveURL turns a (possibly relative) URL into an absolute one against
+// base, rejecting anything using a non-whitelisted protocol.
+func resolveURL(raw string, base *url.URL) (string, bool) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return "", false
+	}
+	if base != nil {
+		u = base.ResolveReference(u)
+	}
+	if !allowedSchemes[strings.ToLower(u.Scheme)] {
+		return "", false
+	}
+	return u.String(), true
+}
+
+func isExternal(href string, base *url.URL) bool {
+	u, err := url.Parse(href)
+	if err != nil || base == nil || base.Host == "" {
+		return true
+	}
+	return !strings.EqualFold(u.Host, base.Host)
+}
+
+// isTrackingPixel treats a 1x1 <img> as a tracking beacon, the convention
+// used by most analytics/tracking pixels.
+func isTrackingPixel(n *html.Node) bool {
+	var width, height string
+	for _, a := range n.Attr {
+		switch a.Key {
+		case "width":
+			width = a.Val
+		case "height":
+			height = a.Val
+		}
+	}
+	return width == "1" && height == "1"
+}
+
+func isTrustedVideoEmbed(n *html.Node) bool {
+	for _, a := range n.Attr {
+		if a.Key != "src" {
+			continue
+		}
+		u, err := url.Parse(a.Val)
+		if err != nil {
+			return false
+		}
+		return trustedVideoHosts[strings.ToLower(u.Host)]
+	}
+	return false
+}
+
+func attrVal(attrs []html.Attribute, key string) string {
+	for _, a := range attrs {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+// detach clears n's tree pointers so it can be appended under a new parent;
+// html.Node.AppendChild panics on a node that still belongs to a tree.
+func detach(n *html.Node) *html.Node {
+	n.Parent = nil
+	n.PrevSibling = nil
+	n.NextSibling = nil
+	return n
+}
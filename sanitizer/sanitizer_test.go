@@ -0,0 +1,70 @@
+package sanitizer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSanitizeUnwrapsDisallowedTags(t *testing.T) {
+	out := New().Sanitize(`<p>hello <span>world</span></p>`, "")
+
+	if strings.Contains(out, "<span") {
+		t.Fatalf("expected span to be stripped, got %q", out)
+	}
+	if !strings.Contains(out, "hello") || !strings.Contains(out, "world") {
+		t.Fatalf("expected text content of unwrapped tags to survive, got %q", out)
+	}
+	if !strings.Contains(out, "<p>") {
+		t.Fatalf("expected whitelisted <p> to survive, got %q", out)
+	}
+}
+
+func TestSanitizeDropsScriptAndStyleContent(t *testing.T) {
+	out := New().Sanitize(`<p>before</p><script>var x="stolen"; alert(document.cookie);</script><p>after</p>`, "")
+
+	if strings.Contains(out, "<script") {
+		t.Fatalf("expected <script> tag to be stripped, got %q", out)
+	}
+	if strings.Contains(out, "stolen") || strings.Contains(out, "document.cookie") {
+		t.Fatalf("expected script body to be dropped, not unwrapped as text, got %q", out)
+	}
+
+	out = New().Sanitize(`<style>body { color: red; }</style><p>visible</p>`, "")
+	if strings.Contains(out, "color") {
+		t.Fatalf("expected style body to be dropped, got %q", out)
+	}
+	if !strings.Contains(out, "visible") {
+		t.Fatalf("expected sibling content to survive, got %q", out)
+	}
+}
+
+func TestSanitizeStripsDisallowedSchemes(t *testing.T) {
+	out := New().Sanitize(`<a href="javascript:alert(1)">click</a>`, "https://example.com")
+
+	if strings.Contains(out, "href") {
+		t.Fatalf("expected javascript: href to be dropped, got %q", out)
+	}
+	if !strings.Contains(out, "click") {
+		t.Fatalf("expected anchor text to survive, got %q", out)
+	}
+}
+
+func TestSanitizeResolvesRelativeURLs(t *testing.T) {
+	out := New().Sanitize(`<img src="/images/a.jpg">`, "https://example.com/news/story")
+
+	if !strings.Contains(out, `src="https://example.com/images/a.jpg"`) {
+		t.Fatalf("expected relative src to resolve against base link, got %q", out)
+	}
+}
+
+func TestSanitizeIframeWhitelist(t *testing.T) {
+	trusted := New().Sanitize(`<iframe src="https://www.youtube.com/embed/xyz"></iframe>`, "")
+	if !strings.Contains(trusted, "<iframe") {
+		t.Fatalf("expected trusted video host iframe to survive, got %q", trusted)
+	}
+
+	untrusted := New().Sanitize(`<iframe src="https://ads.example.com/embed"></iframe>`, "")
+	if strings.Contains(untrusted, "<iframe") {
+		t.Fatalf("expected untrusted iframe to be dropped, got %q", untrusted)
+	}
+}